@@ -0,0 +1,227 @@
+// Package cmd builds sqlboiler's cobra command tree as a self-contained,
+// dependency-injected unit. Unlike the old main.go, nothing here touches
+// os.Args, os.Exit or the package-level viper instance, which means New's
+// result can be driven directly from tests or from a larger codegen
+// pipeline that embeds sqlboiler as a library step.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/volatiletech/sqlboiler/boilingcore"
+	"github.com/volatiletech/sqlboiler/internal/cli"
+)
+
+// SQLBoilerVersion is printed by --version.
+const SQLBoilerVersion = "3.0.0-pre"
+
+// application holds everything a subcommand needs to do its work: its own
+// viper instance, the boilingcore configs built up during PreRunE and the
+// states built from them during RunE, and where output goes. It's threaded through cmd.Context() rather than living
+// in package globals.
+type application struct {
+	viper  *viper.Viper
+	stdout io.Writer
+	stderr io.Writer
+
+	configFile string
+	profile    string
+
+	// configs/states are parallel slices: one entry per generation target.
+	// A plain `sqlboiler <driver>` invocation populates exactly one of each;
+	// a `[[targets]]` config can populate several.
+	configs []*boilingcore.Config
+	states  []*boilingcore.State
+}
+
+// Option configures the command tree New returns.
+type Option func(*application)
+
+// WithViper injects a *viper.Viper instance instead of creating a fresh one,
+// letting callers pre-seed config or share it across invocations.
+func WithViper(v *viper.Viper) Option {
+	return func(a *application) { a.viper = v }
+}
+
+// WithStdout overrides where generated output goes; defaults to os.Stdout.
+func WithStdout(w io.Writer) Option {
+	return func(a *application) { a.stdout = w }
+}
+
+// WithStderr overrides where error output goes; defaults to os.Stderr.
+func WithStderr(w io.Writer) Option {
+	return func(a *application) { a.stderr = w }
+}
+
+type contextKey int
+
+const appContextKey contextKey = 0
+
+// fromContext retrieves the application a PersistentPreRunE attached to
+// cmd's context. It panics if called outside of New's command tree, which
+// would be a programming error, not a runtime one.
+func fromContext(cmd *cobra.Command) *application {
+	return cmd.Context().Value(appContextKey).(*application)
+}
+
+// Debug reports whether debug output was requested for cmd's invocation,
+// consulting the same flag/env/config-file precedence as everything else
+// (application.viper), not just whether --debug was parsed onto this
+// particular command's flag set. application itself is unexported, so this
+// is how an embedder like main.go reads the resolved value back after
+// Execute returns. It's safe to call even if cmd never reached
+// PersistentPreRunE (e.g. Execute failed while parsing flags), falling back
+// to the flag's own parsed/default value in that case.
+func Debug(cmd *cobra.Command) bool {
+	if app, ok := cmd.Context().Value(appContextKey).(*application); ok {
+		return app.viper.GetBool("debug")
+	}
+
+	debug, _ := cmd.Flags().GetBool("debug")
+	return debug
+}
+
+// New builds the sqlboiler command tree. Callers drive it with
+// cmd.SetArgs(...) and cmd.Execute() or cmd.ExecuteContext(...); main.go is
+// now just a thin wrapper around this.
+func New(opts ...Option) *cobra.Command {
+	app := &application{
+		viper:  viper.New(),
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+	for _, opt := range opts {
+		opt(app)
+	}
+
+	root := &cobra.Command{
+		Use:   "sqlboiler [flags] <driver>",
+		Short: "SQL Boiler generates an ORM tailored to your database schema.",
+		Long: "SQL Boiler generates a Go ORM from template files, tailored to your database schema.\n" +
+			`Complete documentation is available at http://github.com/volatiletech/sqlboiler`,
+		Example:       `sqlboiler generate psql`,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := initConfig(app); err != nil {
+				return err
+			}
+			cmd.SetContext(context.WithValue(cmd.Context(), appContextKey, app))
+			return nil
+		},
+	}
+
+	// The root command still generates directly, so `sqlboiler psql` keeps
+	// working as an alias for `sqlboiler generate psql`.
+	root.Args = cobra.MaximumNArgs(1)
+	root.PreRunE = generatePreRun
+	root.RunE = generateRun
+	root.PostRunE = generatePostRun
+
+	root.PersistentFlags().StringVarP(&app.configFile, "config", "c", "", "Filename of config file to override default lookup")
+	root.PersistentFlags().StringVar(&app.profile, "profile", "", "Load a sqlboiler.<profile>.toml overlay on top of the base config (env: SQLBOILER_PROFILE)")
+	root.PersistentFlags().BoolP("debug", "d", false, "Debug mode prints stack traces on error")
+	root.PersistentFlags().BoolP("version", "", false, "Print the version")
+
+	addGenerateFlags(app, root)
+
+	root.AddCommand(generateCmd(app))
+	root.AddCommand(initCmd(app))
+	root.AddCommand(driversCmd(app))
+	root.AddCommand(diffCmd(app))
+	root.AddCommand(configCmd(app))
+
+	app.viper.BindPFlags(root.PersistentFlags())
+	app.viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	app.viper.AutomaticEnv()
+
+	return root
+}
+
+// initConfig loads the base sqlboiler.{toml,yaml,json} and, if a profile is
+// selected, merges a sqlboiler.<profile>.toml overlay on top of it. The
+// resulting precedence, highest first, is:
+//
+//	CLI flags > env vars > profile overlay > base config file > driver defaults
+//
+// CLI flags and env vars win because they're bound directly onto v and
+// consulted ahead of the file-backed settings by viper's own precedence
+// rules (viper checks explicit Set calls, then flags, then env, then config);
+// this function is only responsible for getting the two files merged in the
+// right order.
+func initConfig(app *application) error {
+	v := app.viper
+
+	if len(app.configFile) != 0 {
+		v.SetConfigFile(app.configFile)
+		if err := v.ReadInConfig(); err != nil {
+			return err
+		}
+		return mergeProfile(v, app.profile)
+	}
+
+	v.SetConfigName("sqlboiler")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		wd = "./"
+	}
+	v.AddConfigPath(wd)
+
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); len(configHome) > 0 {
+		v.AddConfigPath(filepath.Join(configHome, "sqlboiler"))
+	} else {
+		v.AddConfigPath(filepath.Join(os.Getenv("HOME"), ".config/sqlboiler"))
+	}
+
+	// Ignore errors here, fallback to other validation methods. Users can
+	// use environment variables if a config is not found.
+	_ = v.ReadInConfig()
+
+	return mergeProfile(v, app.profile)
+}
+
+// mergeProfile looks for a sqlboiler.<profile>.* overlay alongside the base
+// config file (or the working directory, if no base config was found) and
+// merges it on top of v with viper.MergeConfig. The profile name is taken
+// from --profile, falling back to SQLBOILER_PROFILE; no profile selected is
+// not an error, it just means there's nothing to overlay.
+func mergeProfile(v *viper.Viper, profile string) error {
+	if len(profile) == 0 {
+		profile = os.Getenv("SQLBOILER_PROFILE")
+	}
+	if len(profile) == 0 {
+		return nil
+	}
+
+	dir := "."
+	if base := v.ConfigFileUsed(); len(base) > 0 {
+		dir = filepath.Dir(base)
+	}
+
+	for _, ext := range []string{"toml", "yaml", "yml", "json"} {
+		overlay := filepath.Join(dir, fmt.Sprintf("sqlboiler.%s.%s", profile, ext))
+
+		f, err := os.Open(overlay)
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+
+		v.SetConfigType(ext)
+		if err := v.MergeConfig(f); err != nil {
+			return errors.Wrapf(err, "could not merge profile overlay %s", overlay)
+		}
+		return nil
+	}
+
+	return cli.CommandFailure(fmt.Sprintf("no config overlay found for profile %q", profile))
+}
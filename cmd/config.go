@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// secretKeyHints flags a settings key as sensitive if its name contains any
+// of these substrings, case-insensitively.
+var secretKeyHints = []string{"password", "pass", "secret", "token", "key"}
+
+// configCmd builds the `sqlboiler config` command group.
+func configCmd(app *application) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect sqlboiler's effective configuration",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show",
+		Short: "Print the effective merged settings (flag > env > profile overlay > base file > driver defaults), secrets redacted",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app := fromContext(cmd)
+			printSettings(app.stdout, "", redactSecrets(app.viper.AllSettings()))
+			return nil
+		},
+	})
+
+	return cmd
+}
+
+// redactSecrets walks a settings tree replacing any value whose key looks
+// like a credential with a fixed placeholder.
+func redactSecrets(settings map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(settings))
+
+	for k, v := range settings {
+		if nested, ok := v.(map[string]interface{}); ok {
+			out[k] = redactSecrets(nested)
+			continue
+		}
+
+		if looksLikeSecret(k) {
+			out[k] = "********"
+		} else {
+			out[k] = v
+		}
+	}
+
+	return out
+}
+
+func looksLikeSecret(key string) bool {
+	lower := strings.ToLower(key)
+	for _, hint := range secretKeyHints {
+		if strings.Contains(lower, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// printSettings renders a settings tree as dotted.path = value lines, sorted
+// for stable output.
+func printSettings(w io.Writer, prefix string, settings map[string]interface{}) {
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		path := k
+		if len(prefix) > 0 {
+			path = prefix + "." + k
+		}
+
+		if nested, ok := settings[k].(map[string]interface{}); ok {
+			printSettings(w, path, nested)
+			continue
+		}
+
+		fmt.Fprintf(w, "%s = %v\n", path, settings[k])
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLooksLikeSecret(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"password", true},
+		{"DB_PASSWORD", true},
+		{"api_token", true},
+		{"secret_key", true},
+		{"host", false},
+		{"port", false},
+	}
+
+	for _, tt := range tests {
+		if got := looksLikeSecret(tt.key); got != tt.want {
+			t.Errorf("looksLikeSecret(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	settings := map[string]interface{}{
+		"psql": map[string]interface{}{
+			"user":     "boiler",
+			"password": "hunter2",
+		},
+		"debug": false,
+	}
+
+	got := redactSecrets(settings)
+
+	psql := got["psql"].(map[string]interface{})
+	if psql["password"] != "********" {
+		t.Errorf("password not redacted, got %v", psql["password"])
+	}
+	if psql["user"] != "boiler" {
+		t.Errorf("non-secret key mutated, got %v", psql["user"])
+	}
+	if got["debug"] != false {
+		t.Errorf("top-level non-secret key mutated, got %v", got["debug"])
+	}
+}
+
+func TestPrintSettings(t *testing.T) {
+	var buf bytes.Buffer
+	printSettings(&buf, "", map[string]interface{}{
+		"b": "2",
+		"a": map[string]interface{}{
+			"c": "1",
+		},
+	})
+
+	want := "a.c = 1\nb = 2\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/volatiletech/sqlboiler/drivers"
+	"github.com/volatiletech/sqlboiler/internal/cli"
+)
+
+// schemaChange is one difference between the previously recorded schema
+// snapshot and the live database, keyed by its JSON path (e.g.
+// "tables[users].columns[email]").
+type schemaChange struct {
+	Path string      `json:"path"`
+	Kind string      `json:"kind"` // added, removed, altered
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// diffCmd builds the `sqlboiler diff <driver>` subcommand, which compares
+// the live database schema against the snapshot recorded by the last
+// `sqlboiler diff` run and reports what changed. Only diff records that
+// snapshot; `sqlboiler generate` does not write or update it.
+func diffCmd(app *application) *cobra.Command {
+	var asJSON bool
+	var noSave bool
+
+	cmd := &cobra.Command{
+		Use:     "diff <driver>",
+		Short:   "Compare the live database schema against the last recorded snapshot",
+		Example: `sqlboiler diff psql`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			app := fromContext(cmd)
+
+			driverName, err := cli.ResolveDriver(args[0])
+			if err != nil {
+				return err
+			}
+
+			cfg, err := cli.LoadConfig(app.viper, driverName)
+			if err != nil {
+				return err
+			}
+
+			drv, err := drivers.GetDriver(driverName)
+			if err != nil {
+				return err
+			}
+
+			dbInfo, err := drv.Assemble(cfg.DriverConfig)
+			if err != nil {
+				return errors.Wrap(err, "could not assemble live schema")
+			}
+
+			live, err := toGeneric(dbInfo)
+			if err != nil {
+				return err
+			}
+
+			snapshotPath := filepath.Join(cfg.OutFolder, ".sqlboiler-schema.json")
+			previous, err := loadSnapshot(snapshotPath)
+			if err != nil {
+				return err
+			}
+
+			changes := diffGeneric("", previous, live)
+			sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+
+			if asJSON {
+				enc := json.NewEncoder(app.stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(changes); err != nil {
+					return err
+				}
+			} else {
+				printChangesHuman(app.stdout, changes)
+			}
+
+			if !noSave {
+				return saveSnapshot(snapshotPath, live)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print the diff as machine-readable JSON")
+	cmd.Flags().BoolVar(&noSave, "no-save", false, "Don't update the recorded schema snapshot")
+
+	return cmd
+}
+
+func printChangesHuman(w io.Writer, changes []schemaChange) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "no schema changes since the last snapshot")
+		return
+	}
+
+	for _, c := range changes {
+		switch c.Kind {
+		case "added":
+			fmt.Fprintf(w, "+ %s = %v\n", c.Path, c.New)
+		case "removed":
+			fmt.Fprintf(w, "- %s (was %v)\n", c.Path, c.Old)
+		case "altered":
+			fmt.Fprintf(w, "~ %s: %v -> %v\n", c.Path, c.Old, c.New)
+		}
+	}
+}
+
+func toGeneric(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal schema")
+	}
+
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal schema")
+	}
+	return out, nil
+}
+
+func loadSnapshot(path string) (interface{}, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to read schema snapshot %s", path)
+	}
+
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse schema snapshot %s", path)
+	}
+	return out, nil
+}
+
+func saveSnapshot(path string, schema interface{}) error {
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal schema snapshot")
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return errors.Wrap(err, "failed to create output folder")
+		}
+	}
+
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+// diffGeneric walks two decoded-JSON values in lockstep and reports what
+// changed. It doesn't know anything about bdb.DBInfo's actual shape, which
+// keeps it working across drivers whose schemas nest tables/columns
+// differently.
+func diffGeneric(path string, old, new interface{}) []schemaChange {
+	if old == nil && new == nil {
+		return nil
+	}
+
+	// Rather than reporting a missing side as one big added/removed blob,
+	// walk it against an empty map/slice of the same shape so a first run
+	// (no snapshot yet) still reports one change per table instead of a
+	// single change for the whole schema.
+	if old == nil {
+		if m, ok := new.(map[string]interface{}); ok {
+			return diffGeneric(path, map[string]interface{}{}, m)
+		}
+		if s, ok := new.([]interface{}); ok {
+			return diffGeneric(path, []interface{}{}, s)
+		}
+		return []schemaChange{{Path: path, Kind: "added", New: new}}
+	}
+	if new == nil {
+		if m, ok := old.(map[string]interface{}); ok {
+			return diffGeneric(path, m, map[string]interface{}{})
+		}
+		if s, ok := old.([]interface{}); ok {
+			return diffGeneric(path, s, []interface{}{})
+		}
+		return []schemaChange{{Path: path, Kind: "removed", Old: old}}
+	}
+
+	var changes []schemaChange
+
+	oldMap, oldIsMap := old.(map[string]interface{})
+	newMap, newIsMap := new.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := make(map[string]bool)
+		for k := range oldMap {
+			keys[k] = true
+		}
+		for k := range newMap {
+			keys[k] = true
+		}
+
+		for k := range keys {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			changes = append(changes, diffGeneric(childPath, oldMap[k], newMap[k])...)
+		}
+		return changes
+	}
+
+	oldSlice, oldIsSlice := old.([]interface{})
+	newSlice, newIsSlice := new.([]interface{})
+	if oldIsSlice && newIsSlice {
+		return diffSlice(path, oldSlice, newSlice)
+	}
+
+	if old != new {
+		changes = append(changes, schemaChange{Path: path, Kind: "altered", Old: old, New: new})
+	}
+
+	return changes
+}
+
+// diffSlice compares two JSON arrays. Tables and columns are objects with a
+// "name"/"Name" field, so when every element on both sides has one, that
+// field is used as a stable identity and elements are matched by it -
+// inserting or removing a table no longer shifts every later index into a
+// wall of spurious "altered" entries. Arrays whose elements don't carry an
+// identifiable name (e.g. a plain string list) fall back to positional
+// comparison, same as before.
+func diffSlice(path string, oldSlice, newSlice []interface{}) []schemaChange {
+	oldKeyed, oldOK := keyByIdentity(oldSlice)
+	newKeyed, newOK := keyByIdentity(newSlice)
+
+	if !oldOK || !newOK {
+		max := len(oldSlice)
+		if len(newSlice) > max {
+			max = len(newSlice)
+		}
+
+		var changes []schemaChange
+		for i := 0; i < max; i++ {
+			var o, n interface{}
+			if i < len(oldSlice) {
+				o = oldSlice[i]
+			}
+			if i < len(newSlice) {
+				n = newSlice[i]
+			}
+			changes = append(changes, diffGeneric(fmt.Sprintf("%s[%d]", path, i), o, n)...)
+		}
+		return changes
+	}
+
+	names := make(map[string]bool, len(oldKeyed)+len(newKeyed))
+	for name := range oldKeyed {
+		names[name] = true
+	}
+	for name := range newKeyed {
+		names[name] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var changes []schemaChange
+	for _, name := range sorted {
+		childPath := fmt.Sprintf("%s[%s]", path, name)
+		o, inOld := oldKeyed[name]
+		n, inNew := newKeyed[name]
+		switch {
+		case !inOld:
+			changes = append(changes, schemaChange{Path: childPath, Kind: "added", New: n})
+		case !inNew:
+			changes = append(changes, schemaChange{Path: childPath, Kind: "removed", Old: o})
+		default:
+			changes = append(changes, diffGeneric(childPath, o, n)...)
+		}
+	}
+	return changes
+}
+
+// keyByIdentity indexes a JSON array by each element's "name"/"Name" field.
+// ok is false if any element isn't an object or doesn't carry one of those
+// fields, telling the caller to fall back to positional comparison instead.
+func keyByIdentity(elements []interface{}) (map[string]interface{}, bool) {
+	keyed := make(map[string]interface{}, len(elements))
+
+	for _, el := range elements {
+		m, ok := el.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		name, ok := identityOf(m)
+		if !ok {
+			return nil, false
+		}
+
+		keyed[name] = el
+	}
+
+	return keyed, true
+}
+
+func identityOf(m map[string]interface{}) (string, bool) {
+	for _, field := range []string{"name", "Name"} {
+		if v, ok := m[field].(string); ok && len(v) > 0 {
+			return v, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"sort"
+	"testing"
+)
+
+func sortedChanges(changes []schemaChange) []schemaChange {
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func TestDiffGenericNoBaseline(t *testing.T) {
+	live := map[string]interface{}{
+		"tables": []interface{}{
+			map[string]interface{}{"name": "users"},
+			map[string]interface{}{"name": "posts"},
+		},
+	}
+
+	changes := sortedChanges(diffGeneric("", nil, live))
+
+	want := []string{"tables[posts]", "tables[users]"}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i, c := range changes {
+		if c.Path != want[i] {
+			t.Errorf("change %d: got path %q, want %q", i, c.Path, want[i])
+		}
+		if c.Kind != "added" {
+			t.Errorf("change %d: got kind %q, want added", i, c.Kind)
+		}
+	}
+}
+
+func TestDiffGenericSliceMatchesByIdentityNotIndex(t *testing.T) {
+	old := map[string]interface{}{
+		"tables": []interface{}{
+			map[string]interface{}{"name": "users"},
+			map[string]interface{}{"name": "posts"},
+		},
+	}
+	new := map[string]interface{}{
+		"tables": []interface{}{
+			// "comments" inserted before "posts" - a purely positional diff
+			// would report posts as "altered" even though it didn't change.
+			map[string]interface{}{"name": "users"},
+			map[string]interface{}{"name": "comments"},
+			map[string]interface{}{"name": "posts"},
+		},
+	}
+
+	changes := sortedChanges(diffGeneric("", old, new))
+
+	if len(changes) != 1 {
+		t.Fatalf("got %d changes, want 1: %+v", len(changes), changes)
+	}
+	if changes[0].Path != "tables[comments]" || changes[0].Kind != "added" {
+		t.Errorf("got %+v, want a single added change for tables[comments]", changes[0])
+	}
+}
+
+func TestDiffGenericSliceWithoutIdentityFallsBackToPositional(t *testing.T) {
+	old := []interface{}{"a", "b"}
+	new := []interface{}{"a", "c"}
+
+	changes := diffGeneric("tags", old, new)
+
+	if len(changes) != 1 || changes[0].Path != "tags[1]" || changes[0].Kind != "altered" {
+		t.Fatalf("got %+v, want a single altered change at tags[1]", changes)
+	}
+}
+
+func TestDiffGenericAlteredScalar(t *testing.T) {
+	changes := diffGeneric("psql.port", float64(5432), float64(5433))
+
+	if len(changes) != 1 || changes[0].Kind != "altered" {
+		t.Fatalf("got %+v, want a single altered change", changes)
+	}
+}
+
+func TestDiffGenericNoChanges(t *testing.T) {
+	old := map[string]interface{}{"a": "b"}
+	new := map[string]interface{}{"a": "b"}
+
+	if changes := diffGeneric("", old, new); len(changes) != 0 {
+		t.Fatalf("got %+v, want no changes", changes)
+	}
+}
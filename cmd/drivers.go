@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/volatiletech/sqlboiler/drivers"
+	"github.com/volatiletech/sqlboiler/internal/cli"
+)
+
+// driversCmd builds the `sqlboiler drivers` command group, which lets users
+// discover and inspect the sqlboiler-<name> drivers available on their
+// system without having to run a full generation.
+func driversCmd(app *application) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "drivers",
+		Short: "Inspect the sqlboiler drivers available on your system",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the sqlboiler-* driver binaries found on your PATH",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, name := range findDriversOnPath() {
+				fmt.Fprintln(fromContext(cmd).stdout, name)
+			}
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "describe <driver>",
+		Short: "Print the configuration schema a driver advertises",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			driverName, err := cli.ResolveDriver(args[0])
+			if err != nil {
+				return err
+			}
+
+			drv, err := drivers.GetDriver(driverName)
+			if err != nil {
+				return err
+			}
+
+			describer, ok := drv.(drivers.Describer)
+			if !ok {
+				return cli.CommandFailure(fmt.Sprintf("driver %s does not advertise a configuration schema", driverName))
+			}
+
+			// Every binaryDriver satisfies Describer at compile time, so the
+			// type assertion above never actually catches a driver that
+			// predates this protocol - only calling Describe() does, per its
+			// own doc comment.
+			info, err := describer.Describe()
+			if err != nil {
+				return cli.CommandFailure(fmt.Sprintf("driver %s does not advertise a configuration schema", driverName))
+			}
+
+			fmt.Fprint(fromContext(cmd).stdout, info.Pretty())
+			return nil
+		},
+	})
+
+	return cmd
+}
+
+// findDriversOnPath scans $PATH for executables named sqlboiler-*, which is
+// how drivers.RegisterBinary expects to find them.
+func findDriversOnPath() []string {
+	seen := make(map[string]bool)
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasPrefix(entry.Name(), "sqlboiler-") {
+				continue
+			}
+			seen[strings.TrimPrefix(entry.Name(), "sqlboiler-")] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/volatiletech/sqlboiler/boilingcore"
+	"github.com/volatiletech/sqlboiler/internal/cli"
+)
+
+// generateCmd builds the `sqlboiler generate <driver>` subcommand, which is
+// the historical (and still default) behaviour of sqlboiler: run a driver
+// against a database and emit a models package. A `[[targets]]` array in
+// the config lets one invocation generate several targets instead.
+func generateCmd(app *application) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "generate [driver]",
+		Short:         "Generate a Go ORM tailored to your database schema",
+		Example:       `sqlboiler generate psql`,
+		Args:          cobra.MaximumNArgs(1),
+		PreRunE:       generatePreRun,
+		RunE:          generateRun,
+		PostRunE:      generatePostRun,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	// Generate's flags are registered once, as persistent flags on root (see
+	// addGenerateFlags), so this subcommand inherits the very same
+	// FlagSet/viper binding that `sqlboiler <driver>` (the root-level
+	// back-compat alias) parses into - there's nothing to add here.
+	return cmd
+}
+
+// addGenerateFlags registers the flags that control code generation as
+// persistent flags on root, so both root (back-compat `sqlboiler <driver>`)
+// and the `generate` subcommand parse into the exact same FlagSet instead of
+// each getting their own copy bound to viper independently.
+func addGenerateFlags(app *application, root *cobra.Command) {
+	root.PersistentFlags().StringP("output", "o", "models", "The name of the folder to output to")
+	root.PersistentFlags().StringP("pkgname", "p", "models", "The name you wish to assign to your generated package")
+	root.PersistentFlags().StringP("basedir", "", "", "The base directory has the templates and templates_test folders")
+	root.PersistentFlags().StringSliceP("tag", "t", nil, "Struct tags to be included on your models in addition to json, yaml, toml")
+	root.PersistentFlags().StringSliceP("replace", "", nil, "Replace templates by directory: relpath/to_file.tpl:relpath/to_replacement.tpl")
+	root.PersistentFlags().BoolP("no-tests", "", false, "Disable generated go test files")
+	root.PersistentFlags().BoolP("no-hooks", "", false, "Disable hooks feature for your models")
+	root.PersistentFlags().BoolP("no-auto-timestamps", "", false, "Disable automatic timestamps for created_at/updated_at")
+	root.PersistentFlags().BoolP("wipe", "", false, "Delete the output folder (rm -rf) before generation to ensure sanity")
+	root.PersistentFlags().StringP("struct-tag-casing", "", "snake", "Decides the casing for go structure tag names. camel or snake (default snake)")
+	root.PersistentFlags().String("target", "", "Only generate the named [[targets]] entry")
+	root.PersistentFlags().Bool("parallel", false, "Run multiple [[targets]] entries concurrently")
+
+	root.PersistentFlags().MarkHidden("replace")
+}
+
+func generatePreRun(cmd *cobra.Command, args []string) error {
+	app := fromContext(cmd)
+
+	targets, err := cli.LoadTargets(app.viper)
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		if len(args) == 0 {
+			return cli.CommandFailure("must provide a driver name")
+		}
+
+		driverName, err := cli.ResolveDriver(args[0])
+		if err != nil {
+			return err
+		}
+
+		cfg, err := cli.LoadConfig(app.viper, driverName)
+		if err != nil {
+			return err
+		}
+
+		app.configs = []*boilingcore.Config{cfg}
+		return nil
+	}
+
+	if name := app.viper.GetString("target"); len(name) > 0 {
+		targets = filterTargets(targets, name)
+		if len(targets) == 0 {
+			return cli.CommandFailure(fmt.Sprintf("no target named %q", name))
+		}
+	}
+
+	app.configs = make([]*boilingcore.Config, 0, len(targets))
+	for _, t := range targets {
+		cfg, err := cli.LoadConfigForTarget(app.viper, t)
+		if err != nil {
+			return err
+		}
+		app.configs = append(app.configs, cfg)
+	}
+
+	return nil
+}
+
+func filterTargets(targets []cli.Target, name string) []cli.Target {
+	for _, t := range targets {
+		if t.Name == name {
+			return []cli.Target{t}
+		}
+	}
+	return nil
+}
+
+// generateRun builds and runs a boilingcore.State per target, sequentially
+// or concurrently depending on --parallel. NOTE: cli.NewState (boilingcore.New)
+// doesn't expose any way to share its parsed template set between State
+// instances, so running with --parallel avoids serializing targets but each
+// one still pays its own template-parse cost - there's no cache to share
+// from this side of the boilingcore boundary.
+func generateRun(cmd *cobra.Command, args []string) error {
+	app := fromContext(cmd)
+	app.states = make([]*boilingcore.State, len(app.configs))
+
+	if !app.viper.GetBool("parallel") || len(app.configs) == 1 {
+		for i, cfg := range app.configs {
+			state, err := cli.NewState(cfg)
+			if err != nil {
+				return err
+			}
+			app.states[i] = state
+
+			if err := state.Run(true); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(app.configs))
+	for i, cfg := range app.configs {
+		wg.Add(1)
+		go func(i int, cfg *boilingcore.Config) {
+			defer wg.Done()
+
+			state, err := cli.NewState(cfg)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			app.states[i] = state
+			errs[i] = state.Run(true)
+		}(i, cfg)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func generatePostRun(cmd *cobra.Command, args []string) error {
+	app := fromContext(cmd)
+
+	for _, state := range app.states {
+		if state == nil {
+			continue
+		}
+		if err := state.Cleanup(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
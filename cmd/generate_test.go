@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/volatiletech/sqlboiler/internal/cli"
+)
+
+func TestFilterTargets(t *testing.T) {
+	targets := []cli.Target{
+		{Name: "primary"},
+		{Name: "replica"},
+	}
+
+	got := filterTargets(targets, "replica")
+	if len(got) != 1 || got[0].Name != "replica" {
+		t.Fatalf("got %+v, want only the replica target", got)
+	}
+
+	if got := filterTargets(targets, "missing"); got != nil {
+		t.Fatalf("got %+v, want nil for an unknown target name", got)
+	}
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/volatiletech/sqlboiler/drivers"
+	"github.com/volatiletech/sqlboiler/internal/cli"
+)
+
+// initCmd builds the `sqlboiler init <driver>` subcommand, which writes a
+// starter sqlboiler.toml by asking the driver for its configuration schema.
+func initCmd(app *application) *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:     "init <driver>",
+		Short:   "Write a starter sqlboiler.toml for a driver",
+		Example: `sqlboiler init psql`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			driverName, err := cli.ResolveDriver(args[0])
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				if _, err := os.Stat("sqlboiler.toml"); err == nil {
+					return cli.CommandFailure("sqlboiler.toml already exists, use --force to overwrite it")
+				}
+			}
+
+			drv, err := drivers.GetDriver(driverName)
+			if err != nil {
+				return err
+			}
+
+			// Every binaryDriver satisfies Describer at compile time, so a
+			// driver that predates this protocol only reveals that by
+			// erroring out of Describe() itself, per its own doc comment -
+			// not by failing the type assertion. Fall back to a bare
+			// starter config in that case instead of hard-failing init.
+			info := drivers.DriverInfo{Name: driverName}
+			if describer, ok := drv.(drivers.Describer); ok {
+				if described, err := describer.Describe(); err == nil {
+					info = described
+				}
+			}
+
+			return ioutil.WriteFile("sqlboiler.toml", []byte(renderStarterConfig(driverName, info)), 0644)
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing sqlboiler.toml")
+
+	return cmd
+}
+
+// renderStarterConfig builds a starter sqlboiler.toml with one line per
+// config key the driver advertises, pre-filled with its default where one
+// exists. Required keys are left uncommented; optional ones are commented
+// out.
+func renderStarterConfig(driverName string, info drivers.DriverInfo) string {
+	out := fmt.Sprintf("[%s]\n", driverName)
+
+	for _, key := range info.ConfigKeys {
+		comment := ""
+		if !key.Required {
+			comment = "# "
+		}
+
+		value := key.Default
+		if value == nil {
+			value = ""
+		}
+
+		switch key.Kind {
+		case drivers.KindInt, drivers.KindBool:
+			out += fmt.Sprintf("%s%s = %v\n", comment, key.Name, value)
+		default:
+			out += fmt.Sprintf("%s%s = %q\n", comment, key.Name, value)
+		}
+	}
+
+	return out
+}
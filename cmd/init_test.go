@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/volatiletech/sqlboiler/drivers"
+)
+
+func TestRenderStarterConfig(t *testing.T) {
+	info := drivers.DriverInfo{
+		Name: "psql",
+		ConfigKeys: []drivers.ConfigKey{
+			{Name: "host", Kind: drivers.KindString, Required: true},
+			{Name: "port", Kind: drivers.KindInt, Default: 5432, Required: false},
+			{Name: "sslmode", Kind: drivers.KindString, Default: "require", Required: false},
+		},
+	}
+
+	out := renderStarterConfig("psql", info)
+
+	if !strings.HasPrefix(out, "[psql]\n") {
+		t.Fatalf("expected output to start with [psql] section header, got %q", out)
+	}
+	if !strings.Contains(out, `host = ""`) {
+		t.Errorf("expected required key host to be uncommented, got %q", out)
+	}
+	if !strings.Contains(out, "# port = 5432") {
+		t.Errorf("expected optional int key port to be commented with its default, got %q", out)
+	}
+	if !strings.Contains(out, `# sslmode = "require"`) {
+		t.Errorf("expected optional string key sslmode to be commented and quoted, got %q", out)
+	}
+}
@@ -0,0 +1,75 @@
+package drivers
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+
+	"github.com/pkg/errors"
+	"github.com/volatiletech/sqlboiler/bdb"
+)
+
+// binaryDriver talks to an external sqlboiler-<name> executable over JSON on
+// stdin/stdout, which is how sqlboiler has always kept drivers out-of-process
+// so that third parties can ship closed-source or otherwise independently
+// versioned drivers.
+type binaryDriver struct {
+	name string
+	path string
+}
+
+// Assemble hands the config map to the driver binary on stdin and decodes
+// the bdb.DBInfo it prints to stdout.
+func (b binaryDriver) Assemble(config map[string]interface{}) (*bdb.DBInfo, error) {
+	out, err := b.run(nil, config)
+	if err != nil {
+		return nil, err
+	}
+
+	info := new(bdb.DBInfo)
+	if err := json.Unmarshal(out, info); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal dbinfo from driver %s", b.name)
+	}
+
+	return info, nil
+}
+
+// Describe invokes the driver binary with the "describe" verb and decodes
+// the DriverInfo it prints to stdout. Driver binaries that pre-date this
+// protocol will exit non-zero or print something unparseable, in which case
+// the error should be treated by the caller as "no schema available".
+func (b binaryDriver) Describe() (DriverInfo, error) {
+	out, err := b.run([]string{"describe"}, nil)
+	if err != nil {
+		return DriverInfo{}, err
+	}
+
+	var info DriverInfo
+	if err := json.Unmarshal(out, &info); err != nil {
+		return DriverInfo{}, errors.Wrapf(err, "failed to unmarshal driver info from driver %s", b.name)
+	}
+
+	return info, nil
+}
+
+func (b binaryDriver) run(args []string, config map[string]interface{}) ([]byte, error) {
+	cmd := exec.Command(b.path, args...)
+
+	if config != nil {
+		in, err := json.Marshal(config)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal driver config")
+		}
+		cmd.Stdin = bytes.NewReader(in)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "driver %s failed: %s", b.name, stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,63 @@
+package drivers
+
+import "fmt"
+
+// ConfigKeyKind is the primitive type of a driver configuration key, used to
+// decide how to pull it out of viper and how to render it for humans.
+type ConfigKeyKind int
+
+// The kinds of values a driver config key can hold.
+const (
+	KindString ConfigKeyKind = iota
+	KindInt
+	KindBool
+	KindStringSlice
+)
+
+func (k ConfigKeyKind) String() string {
+	switch k {
+	case KindInt:
+		return "int"
+	case KindBool:
+		return "bool"
+	case KindStringSlice:
+		return "[]string"
+	default:
+		return "string"
+	}
+}
+
+// ConfigKey is a single entry in a driver's advertised configuration schema.
+type ConfigKey struct {
+	Name     string        `json:"name"`
+	Kind     ConfigKeyKind `json:"kind"`
+	Default  interface{}   `json:"default,omitempty"`
+	Required bool          `json:"required"`
+	EnvAlias string        `json:"env_alias,omitempty"`
+	Help     string        `json:"help"`
+}
+
+// DriverInfo is the structured description a driver returns in place of
+// sqlboiler having to hardcode its config keys, defaults and ports in a
+// switch statement.
+type DriverInfo struct {
+	Name       string      `json:"name"`
+	ConfigKeys []ConfigKey `json:"config_keys"`
+}
+
+// Pretty renders the driver info as a human-readable listing, used by
+// `sqlboiler drivers describe`.
+func (d DriverInfo) Pretty() string {
+	out := fmt.Sprintf("driver: %s\n", d.Name)
+	for _, k := range d.ConfigKeys {
+		req := ""
+		if k.Required {
+			req = " (required)"
+		}
+		out += fmt.Sprintf("  %-20s %-10s default=%v%s - %s\n", k.Name, k.Kind, k.Default, req, k.Help)
+		if k.EnvAlias != "" {
+			out += fmt.Sprintf("  %-20s env: %s\n", "", k.EnvAlias)
+		}
+	}
+	return out
+}
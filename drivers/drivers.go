@@ -0,0 +1,43 @@
+// Package drivers implements the out-of-process protocol sqlboiler uses to
+// talk to the various sqlboiler-<name> driver binaries. Drivers translate a
+// config map into a bdb.DBInfo describing the schema to generate models for.
+package drivers
+
+import (
+	"github.com/pkg/errors"
+	"github.com/volatiletech/sqlboiler/bdb"
+)
+
+// Interface abstracts a driver capable of assembling a bdb.DBInfo from a
+// config map. All current drivers are out-of-process binaries wired up via
+// RegisterBinary, but the interface itself makes no assumption about that.
+type Interface interface {
+	Assemble(config map[string]interface{}) (*bdb.DBInfo, error)
+}
+
+// Describer is implemented by drivers that can advertise their own
+// configuration schema instead of sqlboiler having to hardcode it. Binary
+// drivers always satisfy this interface; a driver binary that doesn't
+// understand the "describe" verb shows up as an error from Describe, and
+// callers should fall back to the historical required-keys list.
+type Describer interface {
+	Describe() (DriverInfo, error)
+}
+
+var registry = map[string]string{}
+
+// RegisterBinary records the path to an external driver binary under name so
+// it can later be resolved with GetDriver.
+func RegisterBinary(name, path string) {
+	registry[name] = path
+}
+
+// GetDriver resolves a driver previously registered with RegisterBinary.
+func GetDriver(name string) (Interface, error) {
+	path, ok := registry[name]
+	if !ok {
+		return nil, errors.Errorf("no driver registered for %q, was RegisterBinary called?", name)
+	}
+
+	return binaryDriver{name: name, path: path}, nil
+}
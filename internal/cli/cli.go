@@ -0,0 +1,359 @@
+// Package cli contains the plumbing shared by sqlboiler's cobra subcommands:
+// driver resolution, config loading and boilingcore.State construction. It
+// exists so that generate, init and diff can all build the same
+// boilingcore.Config/State without main.go accumulating duplicated logic.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kat-co/vala"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"github.com/volatiletech/sqlboiler/boilingcore"
+	"github.com/volatiletech/sqlboiler/drivers"
+	"github.com/volatiletech/sqlboiler/importers"
+)
+
+// CommandFailure is a user-facing error; the CLI prints its message followed
+// by --help output instead of a raw stack trace.
+type CommandFailure string
+
+func (c CommandFailure) Error() string {
+	return string(c)
+}
+
+// ResolveDriver registers a driver binary (either found on PATH as
+// sqlboiler-<name>, or an explicit path to an executable) and returns its
+// canonical name.
+func ResolveDriver(driverOrPath string) (name string, err error) {
+	name = driverOrPath
+	path := driverOrPath
+
+	if strings.ContainsRune(name, os.PathSeparator) {
+		name = strings.Replace(filepath.Base(name), "sqlboiler-", "", 1)
+	} else {
+		path = "sqlboiler-" + path
+		if p, err := exec.LookPath(path); err == nil {
+			path = p
+		}
+	}
+
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", errors.Wrap(err, "could not find absolute path to driver")
+	}
+
+	drivers.RegisterBinary(name, path)
+	return name, nil
+}
+
+// LoadConfig builds a boilingcore.Config for driverName out of the
+// viper-bound CLI flags plus whatever configuration schema the driver itself
+// advertises (see drivers.Describer).
+func LoadConfig(v *viper.Viper, driverName string) (*boilingcore.Config, error) {
+	cfg := &boilingcore.Config{
+		DriverName:       driverName,
+		OutFolder:        v.GetString("output"),
+		PkgName:          v.GetString("pkgname"),
+		BaseDir:          v.GetString("basedir"),
+		Debug:            v.GetBool("debug"),
+		NoTests:          v.GetBool("no-tests"),
+		NoHooks:          v.GetBool("no-hooks"),
+		NoAutoTimestamps: v.GetBool("no-auto-timestamps"),
+		Wipe:             v.GetBool("wipe"),
+		StructTagCasing:  strings.ToLower(v.GetString("struct-tag-casing")),
+		Tags:             v.GetStringSlice("tag"),
+		Replacements:     v.GetStringSlice("replace"),
+	}
+
+	cfg.DriverConfig = map[string]interface{}{
+		"whitelist": v.GetStringSlice(driverName + ".whitelist"),
+		"blacklist": v.GetStringSlice(driverName + ".blacklist"),
+	}
+
+	validationRules, err := configureDriverDefaults(v, driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := vala.BeginValidation().Validate(validationRules...).Check(); err != nil {
+		return nil, CommandFailure(err.Error())
+	}
+
+	for _, key := range allKeys(v, driverName) {
+		prefixedKey := fmt.Sprintf("%s.%s", driverName, key)
+		cfg.DriverConfig[key] = v.Get(prefixedKey)
+	}
+
+	cfg.Imports = configureImports(v)
+
+	return cfg, nil
+}
+
+// NewState constructs a boilingcore.State from a previously loaded Config.
+func NewState(cfg *boilingcore.Config) (*boilingcore.State, error) {
+	return boilingcore.New(cfg)
+}
+
+// Target is one entry of a `[[targets]]` array in sqlboiler.toml, letting a
+// single invocation generate models for several databases/schemas at once.
+// Config holds whatever connection settings (user, host, port, ...) the
+// driver itself expects; they're the same keys that would otherwise live
+// under the driver's own top-level table.
+type Target struct {
+	Name      string                 `mapstructure:"name"`
+	Driver    string                 `mapstructure:"driver"`
+	Output    string                 `mapstructure:"output"`
+	PkgName   string                 `mapstructure:"pkgname"`
+	Whitelist []string               `mapstructure:"whitelist"`
+	Blacklist []string               `mapstructure:"blacklist"`
+
+	// Imports overrides the global [imports] table for this target alone;
+	// it's pulled out as its own field (rather than left in Config) so it
+	// can be applied against the top-level "imports" key that
+	// configureImports actually reads, instead of silently landing under
+	// the driver's own namespace (e.g. psql.imports) where nothing looks
+	// for it.
+	Imports map[string]interface{} `mapstructure:"imports"`
+
+	Config map[string]interface{} `mapstructure:",remain"`
+}
+
+// LoadTargets reads the `[[targets]]` array out of v, if present. A nil,
+// nil return means no targets section was configured and callers should
+// fall back to the single-target flow driven by a CLI argument.
+func LoadTargets(v *viper.Viper) ([]Target, error) {
+	if !v.IsSet("targets") {
+		return nil, nil
+	}
+
+	var targets []Target
+	if err := v.UnmarshalKey("targets", &targets); err != nil {
+		return nil, errors.Wrap(err, "could not parse [[targets]] config")
+	}
+
+	return targets, nil
+}
+
+// LoadConfigForTarget builds a boilingcore.Config for one [[targets]] entry.
+// It reuses the single-target validation/defaulting path by seeding the
+// driver's own viper namespace with the target's connection settings before
+// calling LoadConfig. Overrides are applied to a scoped copy of v rather
+// than v itself, so a target that sets e.g. psql.schema can't leak that
+// value into a sibling target sharing the same driver that expects to fall
+// back to the base [psql] config instead.
+func LoadConfigForTarget(v *viper.Viper, t Target) (*boilingcore.Config, error) {
+	if len(t.Driver) == 0 {
+		return nil, CommandFailure(fmt.Sprintf("target %q is missing a driver", t.Name))
+	}
+
+	scoped := viper.New()
+	if err := scoped.MergeConfigMap(v.AllSettings()); err != nil {
+		return nil, errors.Wrapf(err, "target %q", t.Name)
+	}
+	scoped.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	scoped.AutomaticEnv()
+
+	for key, value := range t.Config {
+		scoped.Set(t.Driver+"."+key, value)
+	}
+	if len(t.Whitelist) > 0 {
+		scoped.Set(t.Driver+".whitelist", t.Whitelist)
+	}
+	if len(t.Blacklist) > 0 {
+		scoped.Set(t.Driver+".blacklist", t.Blacklist)
+	}
+	if len(t.Imports) > 0 {
+		scoped.Set("imports", t.Imports)
+	}
+
+	driverName, err := ResolveDriver(t.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := LoadConfig(scoped, driverName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "target %q", t.Name)
+	}
+
+	if len(t.Output) > 0 {
+		cfg.OutFolder = t.Output
+	}
+	if len(t.PkgName) > 0 {
+		cfg.PkgName = t.PkgName
+	}
+
+	return cfg, nil
+}
+
+// configureDriverDefaults asks the driver for its advertised configuration
+// schema and uses it to seed viper defaults and build vala validation rules.
+// Every binaryDriver satisfies drivers.Describer at compile time, so a type
+// assertion alone can't tell a driver that actually understands "describe"
+// apart from one that predates the protocol - that's only discoverable by
+// calling Describe() and seeing whether it errors, per its own doc comment.
+// Drivers that fail it fall back to the historical bare-minimum
+// requirements.
+func configureDriverDefaults(v *viper.Viper, driverName string) ([]vala.Checker, error) {
+	drv, err := drivers.GetDriver(driverName)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not resolve driver")
+	}
+
+	return driverDefaults(v, driverName, drv), nil
+}
+
+// driverDefaults is configureDriverDefaults' testable core: given an
+// already-resolved driver, it does the actual Describer/legacy branching.
+// Splitting it out lets tests exercise that branching with a fake
+// drivers.Interface/Describer instead of going through the registry, which
+// only ever resolves real sqlboiler-<name> binaries.
+func driverDefaults(v *viper.Viper, driverName string, drv drivers.Interface) []vala.Checker {
+	describer, ok := drv.(drivers.Describer)
+	if !ok {
+		return legacyValidationRules(v, driverName)
+	}
+
+	info, err := describer.Describe()
+	if err != nil {
+		return legacyValidationRules(v, driverName)
+	}
+
+	var validationRules []vala.Checker
+	for _, key := range info.ConfigKeys {
+		fullKey := fmt.Sprintf("%s.%s", driverName, key.Name)
+		if key.Default != nil {
+			v.SetDefault(fullKey, key.Default)
+		}
+
+		if !key.Required {
+			continue
+		}
+
+		switch key.Kind {
+		case drivers.KindInt:
+			validationRules = append(validationRules, vala.Not(vala.Equals(v.GetInt(fullKey), 0, fullKey)))
+		default:
+			validationRules = append(validationRules, vala.StringNotEmpty(v.GetString(fullKey), fullKey))
+		}
+	}
+
+	return validationRules
+}
+
+// legacyValidationRules is the pre-Describer fallback for driver binaries
+// that don't yet advertise their own configuration schema - which, as of
+// this series, is every existing psql/mysql/mssql/crdb binary. It exists
+// purely to preserve the defaults and required-key list the old
+// `switch driverName` block in main.go used to set, until those drivers
+// are updated to implement drivers.Describer themselves.
+func legacyValidationRules(v *viper.Viper, driverName string) []vala.Checker {
+	required := []string{"user", "host", "port", "dbname", "sslmode"}
+
+	switch driverName {
+	case "psql":
+		v.SetDefault("psql.schema", "public")
+		v.SetDefault("psql.port", 5432)
+		v.SetDefault("psql.sslmode", "require")
+		required = append(required, "schema")
+	case "mysql":
+		v.Set("mysql.schema", v.GetString("mysql.dbname"))
+		v.SetDefault("mysql.sslmode", "true")
+		v.SetDefault("mysql.port", 3306)
+	case "mssql":
+		v.SetDefault("mssql.schema", "dbo")
+		v.SetDefault("mssql.sslmode", "true")
+		v.SetDefault("mssql.port", 1433)
+		required = append(required, "schema")
+	case "crdb":
+		v.SetDefault("crdb.schema", "public")
+		v.SetDefault("crdb.port", 26257)
+		v.SetDefault("crdb.sslmode", "require")
+		required = append(required, "schema")
+	}
+
+	var validationRules []vala.Checker
+	for _, r := range required {
+		key := fmt.Sprintf("%s.%s", driverName, r)
+		switch r {
+		case "port":
+			validationRules = append(validationRules, vala.Not(vala.Equals(v.GetInt(key), 0, key)))
+		default:
+			validationRules = append(validationRules, vala.StringNotEmpty(v.GetString(key), key))
+		}
+	}
+
+	return validationRules
+}
+
+func configureImports(v *viper.Viper) importers.Collection {
+	imports := importers.NewDefaultImports()
+
+	mustMap := func(m importers.Map, err error) importers.Map {
+		if err != nil {
+			panic("failed to change viper interface into importers.Map: " + err.Error())
+		}
+
+		return m
+	}
+
+	if v.IsSet("imports.all.standard") {
+		imports.All.Standard = v.GetStringSlice("imports.all.standard")
+	}
+	if v.IsSet("imports.all.third_party") {
+		imports.All.ThirdParty = v.GetStringSlice("imports.all.third_party")
+	}
+	if v.IsSet("imports.test.standard") {
+		imports.Test.Standard = v.GetStringSlice("imports.test.standard")
+	}
+	if v.IsSet("imports.test.third_party") {
+		imports.Test.ThirdParty = v.GetStringSlice("imports.test.third_party")
+	}
+	if v.IsSet("imports.singleton") {
+		imports.Singleton = mustMap(importers.MapFromInterface(v.Get("imports.singleton")))
+	}
+	if v.IsSet("imports.test_singleton") {
+		imports.TestSingleton = mustMap(importers.MapFromInterface(v.Get("imports.test_singleton")))
+	}
+	if v.IsSet("imports.test_main") {
+		imports.TestSingleton = mustMap(importers.MapFromInterface(v.Get("imports.test_main")))
+	}
+	if v.IsSet("imports.based_on_type") {
+		imports.TestSingleton = mustMap(importers.MapFromInterface(v.Get("imports.based_on_type")))
+	}
+
+	return imports
+}
+
+func allKeys(v *viper.Viper, prefix string) []string {
+	keys := make(map[string]bool)
+
+	prefix = prefix + "."
+
+	for _, e := range os.Environ() {
+		splits := strings.SplitN(e, "=", 2)
+		key := strings.Replace(strings.ToLower(splits[0]), "_", ".", -1)
+
+		if strings.HasPrefix(key, prefix) {
+			keys[strings.Replace(key, prefix, "", -1)] = true
+		}
+	}
+
+	for _, key := range v.AllKeys() {
+		if strings.HasPrefix(key, prefix) {
+			keys[strings.Replace(key, prefix, "", -1)] = true
+		}
+	}
+
+	keySlice := make([]string, 0, len(keys))
+	for k := range keys {
+		keySlice = append(keySlice, k)
+	}
+	return keySlice
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/kat-co/vala"
+	"github.com/spf13/viper"
+	"github.com/volatiletech/sqlboiler/bdb"
+	"github.com/volatiletech/sqlboiler/drivers"
+)
+
+// fakeDriver is a minimal drivers.Interface used to exercise
+// configureDriverDefaults/driverDefaults without going through
+// drivers.GetDriver, which only ever resolves real sqlboiler-<name>
+// binaries. Its Describe can return either a schema or an error, covering
+// both branches driverDefaults has to choose between.
+type fakeDriver struct {
+	describeInfo drivers.DriverInfo
+	describeErr  error
+}
+
+func (f fakeDriver) Assemble(config map[string]interface{}) (*bdb.DBInfo, error) {
+	return &bdb.DBInfo{}, nil
+}
+
+func (f fakeDriver) Describe() (drivers.DriverInfo, error) {
+	return f.describeInfo, f.describeErr
+}
+
+func TestLegacyValidationRulesSetsPerDriverDefaults(t *testing.T) {
+	tests := []struct {
+		driverName   string
+		wantDefaults map[string]interface{}
+	}{
+		{
+			driverName:   "psql",
+			wantDefaults: map[string]interface{}{"psql.schema": "public", "psql.port": 5432, "psql.sslmode": "require"},
+		},
+		{
+			driverName:   "mssql",
+			wantDefaults: map[string]interface{}{"mssql.schema": "dbo", "mssql.port": 1433, "mssql.sslmode": "true"},
+		},
+		{
+			driverName:   "crdb",
+			wantDefaults: map[string]interface{}{"crdb.schema": "public", "crdb.port": 26257, "crdb.sslmode": "require"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.driverName, func(t *testing.T) {
+			v := viper.New()
+			legacyValidationRules(v, tt.driverName)
+
+			for key, want := range tt.wantDefaults {
+				switch want := want.(type) {
+				case int:
+					if got := v.GetInt(key); got != want {
+						t.Errorf("%s: got %d, want %d", key, got, want)
+					}
+				default:
+					if got := v.GetString(key); got != want {
+						t.Errorf("%s: got %q, want %q", key, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLegacyValidationRulesMysqlDerivesSchemaFromDBName(t *testing.T) {
+	v := viper.New()
+	v.Set("mysql.dbname", "my_db")
+
+	legacyValidationRules(v, "mysql")
+
+	if got := v.GetString("mysql.schema"); got != "my_db" {
+		t.Errorf("got mysql.schema = %q, want it derived from mysql.dbname (%q)", got, "my_db")
+	}
+	if got := v.GetInt("mysql.port"); got != 3306 {
+		t.Errorf("got mysql.port = %d, want the mysql default 3306", got)
+	}
+}
+
+func TestLegacyValidationRulesRequiredKeys(t *testing.T) {
+	v := viper.New()
+	rules := legacyValidationRules(v, "psql")
+
+	// psql requires user/host/port/dbname/sslmode/schema; none are set
+	// (schema/port/sslmode get defaults, but user/host/dbname don't), so
+	// validation should fail.
+	if err := vala.BeginValidation().Validate(rules...).Check(); err == nil {
+		t.Fatal("expected validation to fail with no user/host/dbname set")
+	}
+
+	v.Set("psql.user", "boiler")
+	v.Set("psql.host", "localhost")
+	v.Set("psql.dbname", "boilerdb")
+	rules = legacyValidationRules(v, "psql")
+
+	if err := vala.BeginValidation().Validate(rules...).Check(); err != nil {
+		t.Fatalf("expected validation to pass once required keys are set, got: %v", err)
+	}
+}
+
+func TestDriverDefaultsFallsBackToLegacyOnDescribeError(t *testing.T) {
+	v := viper.New()
+	v.Set("psql.user", "boiler")
+	v.Set("psql.host", "localhost")
+	v.Set("psql.dbname", "boilerdb")
+
+	drv := fakeDriver{describeErr: errors.New(`unknown command "describe"`)}
+
+	rules := driverDefaults(v, "psql", drv)
+
+	// Falling back to the legacy path should still set psql's schema
+	// default, proving Describe()'s error (not a failed type assertion)
+	// drove the fallback.
+	if got := v.GetString("psql.schema"); got != "public" {
+		t.Errorf("got psql.schema = %q, want the legacy psql default %q", got, "public")
+	}
+	if err := vala.BeginValidation().Validate(rules...).Check(); err != nil {
+		t.Fatalf("expected validation to pass with required legacy keys set, got: %v", err)
+	}
+}
+
+func TestDriverDefaultsUsesDescribedSchema(t *testing.T) {
+	v := viper.New()
+	drv := fakeDriver{describeInfo: drivers.DriverInfo{
+		Name: "fake",
+		ConfigKeys: []drivers.ConfigKey{
+			{Name: "host", Kind: drivers.KindString, Required: true},
+			{Name: "port", Kind: drivers.KindInt, Default: 1234, Required: false},
+		},
+	}}
+
+	rules := driverDefaults(v, "fake", drv)
+
+	if got := v.GetInt("fake.port"); got != 1234 {
+		t.Errorf("got fake.port = %d, want the described default 1234", got)
+	}
+	if err := vala.BeginValidation().Validate(rules...).Check(); err == nil {
+		t.Fatal("expected validation to fail: described host is required but unset")
+	}
+
+	v.Set("fake.host", "localhost")
+	rules = driverDefaults(v, "fake", drv)
+	if err := vala.BeginValidation().Validate(rules...).Check(); err != nil {
+		t.Fatalf("expected validation to pass once the described required key is set, got: %v", err)
+	}
+}
+
+func TestAllKeys(t *testing.T) {
+	v := viper.New()
+	v.Set("psql.user", "boiler")
+	v.Set("psql.host", "localhost")
+	v.Set("mysql.user", "other")
+
+	os.Setenv("PSQL_PORT", "5432")
+	defer os.Unsetenv("PSQL_PORT")
+
+	keys := allKeys(v, "psql")
+
+	want := map[string]bool{"user": true, "host": true, "port": true}
+	if len(keys) != len(want) {
+		t.Fatalf("got keys %v, want %v", keys, want)
+	}
+	for _, k := range keys {
+		if !want[k] {
+			t.Errorf("unexpected key %q in %v", k, keys)
+		}
+	}
+}
+
+func TestConfigureImports(t *testing.T) {
+	v := viper.New()
+	v.Set("imports.all.standard", []string{"fmt"})
+	v.Set("imports.all.third_party", []string{`"github.com/pkg/errors"`})
+	v.Set("imports.test.standard", []string{"testing"})
+
+	imports := configureImports(v)
+
+	if got := imports.All.Standard; len(got) != 1 || got[0] != "fmt" {
+		t.Errorf("got imports.All.Standard = %v, want [fmt]", got)
+	}
+	if got := imports.All.ThirdParty; len(got) != 1 || got[0] != `"github.com/pkg/errors"` {
+		t.Errorf("got imports.All.ThirdParty = %v, want [\"github.com/pkg/errors\"]", got)
+	}
+	if got := imports.Test.Standard; len(got) != 1 || got[0] != "testing" {
+		t.Errorf("got imports.Test.Standard = %v, want [testing]", got)
+	}
+}